@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -30,9 +31,12 @@ COMMANDS:
                Publish the provided message JSON to SUBJECT subscription. SUBJECT and MESSAGE are required
                Example: /var/vcap/jobs/gorouter/bin/nats_client pub router.register '{"host":"172.217.6.68","port":80,"uris":["bar.example.com"]}'
 
-  save         <FILE> 
+  save         <FILE> [FILTER]...
                Save this gorouter's route table to a json file.
+               Each FILTER is KEY=VALUE, where KEY is 'host', 'host_prefix', 'app_id', 'isolation_segment' or 'private_instance_id'.
+               Only the endpoints that match all filters are saved.
                Example: /var/vcap/jobs/gorouter/bin/nats_client save routes.json'
+               Example: /var/vcap/jobs/gorouter/bin/nats_client save my-app-routes.json host_prefix=my-app. isolation_segment=is1'
 
   load         <FILE>
                Load routes from a json file into this gorouter.
@@ -82,11 +86,27 @@ func main() {
 		}
 	}
 
+	var filters []string
+	if command == "save" && len(os.Args) >= 5 {
+		filters = os.Args[4:]
+	}
+
 	config, err := loadConfig(configPath)
 	if err != nil {
 		panic(err)
 	}
 
+	// save only uses the status endpoint of gorouter, so it does not connect to NATS.
+	if command == "save" {
+		fmt.Fprintf(os.Stderr, "Saving route table to %s\n", filename)
+		err := dumpRoutes(config, filename, filters)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintln(os.Stderr, "Done")
+		return
+	}
+
 	natsOptions, err := natsOptions(config)
 	if err != nil {
 		panic(err)
@@ -125,15 +145,6 @@ func main() {
 		}
 	}
 
-	if command == "save" {
-		fmt.Fprintf(os.Stderr, "Saving route table to %s\n", filename)
-		err := dumpRoutes(config, filename)
-		if err != nil {
-			panic(err)
-		}
-		fmt.Fprintln(os.Stderr, "Done")
-	}
-
 	if command == "load" {
 		fmt.Fprintf(os.Stderr, "Loading route table from %s\n", filename)
 		err := loadRoutes(natsConn, filename)
@@ -246,32 +257,43 @@ func (c *Config) NatsServers() []string {
 	return natsServers
 }
 
-func dumpRoutes(config *Config, filename string) error {
+func dumpRoutes(config *Config, filename string, filters []string) error {
+	routeFilters, err := parseRouteFilters(filters)
+	if err != nil {
+		return err
+	}
+
 	res, err := http.Get(fmt.Sprintf("http://%s:%s@%s:%d/routes", config.Status.User, config.Status.Pass, config.Status.Host, config.Status.Port))
 
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
 		return fmt.Errorf("unexpected status code from /routes: %s", res.Status)
 	}
-	file, err := os.Create(filename)
+
+	var routeTable map[string][]map[string]interface{}
+	dataIn, err := io.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	var jsonObject map[string]interface{}
-	dataIn, err := io.ReadAll(res.Body)
+	err = json.Unmarshal(dataIn, &routeTable)
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(dataIn, &jsonObject)
+	if len(routeFilters) > 0 {
+		routeTable = filterRoutes(routeTable, routeFilters)
+	}
+
+	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
+
 	// Pretty print json so that humans can change it.
-	dataOut, err := json.MarshalIndent(jsonObject, "", "  ")
+	dataOut, err := json.MarshalIndent(routeTable, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -283,6 +305,62 @@ func dumpRoutes(config *Config, filename string) error {
 	return err
 }
 
+// Keys of the filters accepted by the save command.
+var routeFilterKeys = []string{"host", "host_prefix", "app_id", "isolation_segment", "private_instance_id"}
+
+func parseRouteFilters(filters []string) (map[string]string, error) {
+	routeFilters := map[string]string{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q, expected KEY=VALUE", filter)
+		}
+		if !slices.Contains(routeFilterKeys, key) {
+			return nil, fmt.Errorf("unknown filter key %q, expected one of %s", key, strings.Join(routeFilterKeys, ", "))
+		}
+		if _, ok := routeFilters[key]; ok {
+			return nil, fmt.Errorf("filter key %q is given more than once", key)
+		}
+		routeFilters[key] = value
+	}
+	return routeFilters, nil
+}
+
+// filterRoutes keeps the endpoints that match all filters. 'host' and
+// 'host_prefix' match the host of the route key, the other filters match
+// fields of the endpoint. Route keys without matching endpoints are dropped.
+func filterRoutes(routeTable map[string][]map[string]interface{}, routeFilters map[string]string) map[string][]map[string]interface{} {
+	filtered := map[string][]map[string]interface{}{}
+	for uri, endpoints := range routeTable {
+		host, _, _ := strings.Cut(uri, "/")
+		if value, ok := routeFilters["host"]; ok && host != value {
+			continue
+		}
+		if value, ok := routeFilters["host_prefix"]; ok && !strings.HasPrefix(host, value) {
+			continue
+		}
+		for _, endpoint := range endpoints {
+			tags, _ := endpoint["tags"].(map[string]interface{})
+			fields := map[string]interface{}{
+				"app_id":              tags["app_id"],
+				"isolation_segment":   endpoint["isolation_segment"],
+				"private_instance_id": endpoint["private_instance_id"],
+			}
+			matches := true
+			for key, field := range fields {
+				fieldValue, _ := field.(string)
+				if value, ok := routeFilters[key]; ok && fieldValue != value {
+					matches = false
+				}
+			}
+			if matches {
+				filtered[uri] = append(filtered[uri], endpoint)
+			}
+		}
+	}
+	return filtered
+}
+
 // From src/code.cloudfoundry.org/gorouter/mbus/subscriber.go
 type RegistryMessage struct {
 	Host                    string            `json:"host"`
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testConfig(t *testing.T, server *httptest.Server) *Config {
+	t.Helper()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Config{Status: StatusConfig{Host: serverURL.Hostname(), Port: uint16(port), User: "user", Pass: "pass"}}
+}
+
+func readRouteTable(t *testing.T, filename string) map[string][]RouteTableEntry {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routeTable map[string][]RouteTableEntry
+	if err := json.Unmarshal(data, &routeTable); err != nil {
+		t.Fatal(err)
+	}
+	return routeTable
+}
+
+func TestDumpRoutesWithoutFilters(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{"a.example.com":[{"address":"10.0.0.1:8080","protocol":"http1","tls":false,"ttl":120,"tags":null}]}`))
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "routes.json")
+	if err := dumpRoutes(testConfig(t, server), filename, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(queries, []string{""}) {
+		t.Errorf("expected a single request without query, got %q", queries)
+	}
+	routeTable := readRouteTable(t, filename)
+	if got := routeTable["a.example.com"]; len(got) != 1 || got[0].Address != "10.0.0.1:8080" {
+		t.Errorf("unexpected route table %v", routeTable)
+	}
+}
+
+func TestParseRouteFilters(t *testing.T) {
+	routeFilters, err := parseRouteFilters([]string{"host_prefix=my-app.", "app_id=abc", "isolation_segment="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"host_prefix": "my-app.", "app_id": "abc", "isolation_segment": ""}
+	if !reflect.DeepEqual(routeFilters, expected) {
+		t.Errorf("got filters %v, want %v", routeFilters, expected)
+	}
+
+	if _, err := parseRouteFilters([]string{"host_prefix"}); err == nil || !strings.Contains(err.Error(), "expected KEY=VALUE") {
+		t.Errorf("expected an error for a filter without '=', got %v", err)
+	}
+	if _, err := parseRouteFilters([]string{"hots=a.example.com"}); err == nil || !strings.Contains(err.Error(), `unknown filter key "hots"`) {
+		t.Errorf("expected an error for an unknown filter key, got %v", err)
+	}
+	if _, err := parseRouteFilters([]string{"stats=true"}); err == nil || !strings.Contains(err.Error(), `unknown filter key "stats"`) {
+		t.Errorf("expected an error for an unknown filter key, got %v", err)
+	}
+	if _, err := parseRouteFilters([]string{"app_id=abc", "app_id=def"}); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected an error for a repeated filter key, got %v", err)
+	}
+}
+
+func TestDumpRoutesWithFilters(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{
+			"my-app.example.com": [
+				{"address": "10.0.0.1:8080", "tags": {"app_id": "abc"}, "isolation_segment": "is1"},
+				{"address": "10.0.0.2:8080", "tags": {"app_id": "abc"}},
+				{"address": "10.0.0.3:8080", "tags": {"app_id": "def"}, "isolation_segment": "is1"}
+			],
+			"my-app.example.com/api": [{"address": "10.0.0.4:8080", "tags": {"app_id": "abc"}, "isolation_segment": "is1"}],
+			"my-app.example.com/other": [{"address": "10.0.0.5:8080", "tags": {"app_id": "def"}, "isolation_segment": "is1"}],
+			"other.example.com": [{"address": "10.0.0.6:8080", "tags": {"app_id": "abc"}, "isolation_segment": "is1"}]
+		}`))
+	}))
+	defer server.Close()
+	config := testConfig(t, server)
+	filename := filepath.Join(t.TempDir(), "routes.json")
+
+	if err := dumpRoutes(config, filename, []string{"host_prefix=my-app.", "app_id=abc", "isolation_segment=is1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(queries, []string{""}) {
+		t.Errorf("expected a single request without query, got %q", queries)
+	}
+	routeTable := readRouteTable(t, filename)
+	if len(routeTable) != 2 || len(routeTable["my-app.example.com"]) != 1 || routeTable["my-app.example.com"][0].Address != "10.0.0.1:8080" ||
+		len(routeTable["my-app.example.com/api"]) != 1 {
+		t.Errorf("unexpected route table %v", routeTable)
+	}
+
+	if err := dumpRoutes(config, filename, []string{"host=my-app.example.com", "isolation_segment="}); err != nil {
+		t.Fatal(err)
+	}
+	routeTable = readRouteTable(t, filename)
+	if len(routeTable) != 1 || len(routeTable["my-app.example.com"]) != 1 || routeTable["my-app.example.com"][0].Address != "10.0.0.2:8080" {
+		t.Errorf("unexpected route table %v", routeTable)
+	}
+}
+
+func TestDumpRoutesErrors(t *testing.T) {
+	body := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	config := testConfig(t, server)
+	filename := filepath.Join(t.TempDir(), "routes.json")
+
+	if err := dumpRoutes(config, filename, nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected an error for the status code, got %v", err)
+	}
+	body = "not json"
+	if err := dumpRoutes(config, filename, nil); err == nil {
+		t.Error("expected an error for an invalid response body")
+	}
+	if err := dumpRoutes(config, filename, []string{"host"}); err == nil {
+		t.Error("expected an error for an invalid filter")
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, got %v", err)
+	}
+}